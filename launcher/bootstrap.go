@@ -0,0 +1,95 @@
+// First-run dependency bootstrap: installs app/requirements.txt against the
+// resolved interpreter before Kodex starts, then records a marker so later
+// launches skip straight to running the app. Re-runs automatically whenever
+// requirements.txt changes.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+const (
+	depsMarkerFile    = ".deps-installed"
+	requirementsFile  = "requirements.txt"
+	bootstrapProgress = "Kodex is installing dependencies, please wait..."
+)
+
+// ensureDepsInstalled runs `pip install -r requirements.txt` the first time
+// Kodex starts, or whenever requirements.txt's contents change. A missing
+// requirements.txt is not an error: not every build ships one.
+func ensureDepsInstalled(pythonExe string, pythonArgs []string, appDir string) error {
+	reqPath := filepath.Join(appDir, requirementsFile)
+	reqHash, err := hashFile(reqPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("hash %s: %w", requirementsFile, err)
+	}
+
+	markerPath := filepath.Join(appDir, depsMarkerFile)
+	if installed, err := os.ReadFile(markerPath); err == nil && string(installed) == reqHash {
+		log("Dependencies already installed (hash %s)", reqHash[:12])
+		return nil
+	}
+
+	log("Installing dependencies from %s...", requirementsFile)
+
+	progress := startProgressDialog(bootstrapProgress)
+	defer progress.stop()
+
+	args := append(append([]string{}, pythonArgs...), "-m", "pip", "install", "--no-warn-script-location", "-r", reqPath)
+	cmd := exec.Command(pythonExe, args...)
+	cmd.Dir = appDir
+	if debug {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("pip install -r %s: %w", requirementsFile, err)
+	}
+
+	if err := runBootstrapHook(pythonExe, pythonArgs, appDir); err != nil {
+		return fmt.Errorf("kodex_py --bootstrap: %w", err)
+	}
+
+	if err := os.WriteFile(markerPath, []byte(reqHash), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", depsMarkerFile, err)
+	}
+	log("✓ Dependencies installed")
+	return nil
+}
+
+// runBootstrapHook invokes `python -m kodex_py --bootstrap` for any
+// post-install setup the app itself wants to run. A non-zero exit is logged
+// and ignored: not every kodex_py build defines a --bootstrap hook.
+func runBootstrapHook(pythonExe string, pythonArgs []string, appDir string) error {
+	args := append(append([]string{}, pythonArgs...), "-m", "kodex_py", "--bootstrap")
+	cmd := exec.Command(pythonExe, args...)
+	cmd.Dir = appDir
+	if debug {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+	}
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		log("kodex_py --bootstrap exited with code %d (ignoring)", exitErr.ExitCode())
+		return nil
+	}
+	return err
+}
+
+func hashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
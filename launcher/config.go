@@ -0,0 +1,134 @@
+// Optional kodex.json launch profile, read from next to the exe.
+//
+// kodex.json lets power users override the interpreter/module/argv the
+// launcher invokes without rebuilding the Go binary, e.g. to point at a
+// diagnostics script or a dev checkout:
+//
+//	{
+//	  "interpreter": "python\\python.exe",
+//	  "module": "kodex_py",
+//	  "argv": ["run"],
+//	  "profiles": {
+//	    "tray":   { "argv": ["run", "--tray"] },
+//	    "worker": { "module": "kodex_py.worker", "argv": ["run"] }
+//	  }
+//	}
+//
+// Fields at the top level are the defaults; a "--profile NAME" selects a
+// named profile whose fields are merged over those defaults.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const launchConfigFile = "kodex.json"
+
+// launchProfile holds the overridable pieces of the `python -m kodex_py run`
+// invocation. Zero values mean "use the built-in default".
+type launchProfile struct {
+	Interpreter string            `json:"interpreter,omitempty"`
+	Module      string            `json:"module,omitempty"`
+	Script      string            `json:"script,omitempty"`
+	Argv        []string          `json:"argv,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+	Cwd         string            `json:"cwd,omitempty"`
+	Debug       *bool             `json:"debug,omitempty"`
+}
+
+type launchConfig struct {
+	launchProfile
+	Profiles   map[string]launchProfile `json:"profiles,omitempty"`
+	Supervisor supervisorConfig         `json:"supervisor,omitempty"`
+}
+
+// loadLaunchConfig reads kodex.json from dir if present. A missing file is
+// not an error: the launcher falls back to its built-in defaults.
+func loadLaunchConfig(dir string) (*launchConfig, error) {
+	path := filepath.Join(dir, launchConfigFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", launchConfigFile, err)
+	}
+
+	var cfg launchConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", launchConfigFile, err)
+	}
+	return &cfg, nil
+}
+
+// resolveProfile merges the named profile (if any) over the config's
+// top-level defaults. Passing an empty name yields just the defaults.
+func (c *launchConfig) resolveProfile(name string) launchProfile {
+	resolved := c.launchProfile
+	if name == "" {
+		return resolved
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		return resolved
+	}
+	if p.Interpreter != "" {
+		resolved.Interpreter = p.Interpreter
+	}
+	if p.Module != "" {
+		resolved.Module = p.Module
+	}
+	if p.Script != "" {
+		resolved.Script = p.Script
+	}
+	if p.Argv != nil {
+		resolved.Argv = p.Argv
+	}
+	if p.Cwd != "" {
+		resolved.Cwd = p.Cwd
+	}
+	if p.Debug != nil {
+		resolved.Debug = p.Debug
+	}
+	if p.Env != nil {
+		merged := make(map[string]string, len(resolved.Env)+len(p.Env))
+		for k, v := range resolved.Env {
+			merged[k] = v
+		}
+		for k, v := range p.Env {
+			merged[k] = v
+		}
+		resolved.Env = merged
+	}
+	return resolved
+}
+
+// extractFlag pulls a bare boolean flag (e.g. "--no-supervise") out of args,
+// returning whether it was present and the remaining args.
+func extractFlag(args []string, flag string) (bool, []string) {
+	for i, a := range args {
+		if a == flag {
+			remaining := append([]string{}, args[:i]...)
+			remaining = append(remaining, args[i+1:]...)
+			return true, remaining
+		}
+	}
+	return false, args
+}
+
+// extractProfileFlag pulls "--profile NAME" out of args, returning the
+// profile name (empty if not present) and the remaining args.
+func extractProfileFlag(args []string) (string, []string) {
+	for i, a := range args {
+		if a == "--profile" && i+1 < len(args) {
+			remaining := append([]string{}, args[:i]...)
+			remaining = append(remaining, args[i+2:]...)
+			return args[i+1], remaining
+		}
+	}
+	return "", args
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveProfileDefaultsOnly(t *testing.T) {
+	cfg := &launchConfig{
+		launchProfile: launchProfile{Module: "kodex_py", Argv: []string{"run"}},
+	}
+
+	got := cfg.resolveProfile("")
+	want := launchProfile{Module: "kodex_py", Argv: []string{"run"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("resolveProfile(\"\") = %+v, want %+v", got, want)
+	}
+}
+
+func TestResolveProfileUnknownNameReturnsDefaults(t *testing.T) {
+	cfg := &launchConfig{
+		launchProfile: launchProfile{Module: "kodex_py"},
+	}
+
+	got := cfg.resolveProfile("missing")
+	if !reflect.DeepEqual(got, cfg.launchProfile) {
+		t.Errorf("resolveProfile(\"missing\") = %+v, want defaults %+v", got, cfg.launchProfile)
+	}
+}
+
+func TestResolveProfileOverridesOnlySetFields(t *testing.T) {
+	debugOff := false
+	cfg := &launchConfig{
+		launchProfile: launchProfile{
+			Module: "kodex_py",
+			Argv:   []string{"run"},
+			Cwd:    "app",
+			Debug:  &debugOff,
+		},
+		Profiles: map[string]launchProfile{
+			"worker": {Module: "kodex_py.worker"},
+		},
+	}
+
+	got := cfg.resolveProfile("worker")
+	if got.Module != "kodex_py.worker" {
+		t.Errorf("Module = %q, want override %q", got.Module, "kodex_py.worker")
+	}
+	if !reflect.DeepEqual(got.Argv, []string{"run"}) {
+		t.Errorf("Argv = %v, want default preserved %v", got.Argv, []string{"run"})
+	}
+	if got.Cwd != "app" {
+		t.Errorf("Cwd = %q, want default preserved %q", got.Cwd, "app")
+	}
+	if got.Debug == nil || *got.Debug != false {
+		t.Errorf("Debug = %v, want default preserved (false)", got.Debug)
+	}
+}
+
+func TestResolveProfileMergesEnv(t *testing.T) {
+	cfg := &launchConfig{
+		launchProfile: launchProfile{Env: map[string]string{"A": "1", "B": "2"}},
+		Profiles: map[string]launchProfile{
+			"tray": {Env: map[string]string{"B": "overridden", "C": "3"}},
+		},
+	}
+
+	got := cfg.resolveProfile("tray").Env
+	want := map[string]string{"A": "1", "B": "overridden", "C": "3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Env = %v, want %v", got, want)
+	}
+}
+
+func TestExtractFlag(t *testing.T) {
+	present, remaining := extractFlag([]string{"--profile", "tray", "--no-supervise"}, "--no-supervise")
+	if !present {
+		t.Fatal("expected flag to be found")
+	}
+	want := []string{"--profile", "tray"}
+	if !reflect.DeepEqual(remaining, want) {
+		t.Errorf("remaining = %v, want %v", remaining, want)
+	}
+
+	present, remaining = extractFlag([]string{"run"}, "--no-supervise")
+	if present {
+		t.Error("expected flag to be absent")
+	}
+	if !reflect.DeepEqual(remaining, []string{"run"}) {
+		t.Errorf("remaining = %v, want unchanged args", remaining)
+	}
+}
+
+func TestExtractProfileFlag(t *testing.T) {
+	name, remaining := extractProfileFlag([]string{"--profile", "worker", "run"})
+	if name != "worker" {
+		t.Errorf("name = %q, want %q", name, "worker")
+	}
+	if !reflect.DeepEqual(remaining, []string{"run"}) {
+		t.Errorf("remaining = %v, want %v", remaining, []string{"run"})
+	}
+
+	name, remaining = extractProfileFlag([]string{"run"})
+	if name != "" {
+		t.Errorf("name = %q, want empty", name)
+	}
+	if !reflect.DeepEqual(remaining, []string{"run"}) {
+		t.Errorf("remaining = %v, want unchanged args", remaining)
+	}
+}
@@ -0,0 +1,232 @@
+//go:build windows
+
+// Single-instance guard and IPC handoff, mirroring how well-behaved tray
+// apps de-duplicate launches from Explorer double-clicks and file
+// associations.
+//
+// The first Kodex process to start holds a named mutex and runs a named
+// pipe server. Any later launch finds the mutex already held, connects to
+// that pipe, forwards its argv as a single JSON line, and exits without
+// spawning a second Python process.
+//
+// Wire protocol (one JSON object per line, newline-terminated):
+//
+//	{"cmd": "open", "args": ["C:\\path\\to\\file"]}
+//	{"cmd": "show-tray"}
+//	{"cmd": "quit"}
+//
+// The running instance decodes the first line of each connection into an
+// ipcMessage and writes it, re-encoded as one JSON line, to the Python
+// child's stdin, where kodex_py is expected to read and react to it.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Microsoft/go-winio"
+	"golang.org/x/sys/windows"
+)
+
+// instanceKey derives a short, filesystem/registry-safe suffix from the exe
+// path so side-by-side installs (e.g. a dev checkout and an installed
+// build) don't collide on the same mutex/pipe.
+func instanceKey(exe string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(exe)))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+func mutexName(exe string) string {
+	return `Global\KodexLauncher-` + instanceKey(exe)
+}
+
+func pipeName(exe string) string {
+	return `\\.\pipe\kodex-` + instanceKey(exe)
+}
+
+// ipcMessage is the JSON line protocol forwarded between launcher
+// instances and, ultimately, to the running kodex_py process.
+type ipcMessage struct {
+	Cmd  string   `json:"cmd"`
+	Args []string `json:"args,omitempty"`
+}
+
+// acquireSingleInstance tries to become the one true Kodex instance for
+// this exe path. acquired is false (with no error) when another instance
+// already holds the mutex.
+func acquireSingleInstance(exe string) (acquired bool, err error) {
+	name, err := windows.UTF16PtrFromString(mutexName(exe))
+	if err != nil {
+		return false, err
+	}
+
+	_, err = windows.CreateMutex(nil, false, name)
+	if err != nil {
+		if err == windows.ERROR_ALREADY_EXISTS {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// dialPipeRetryInterval/dialPipeRetryTimeout bound how long
+// forwardToRunningInstance waits for the running instance's pipe to appear.
+// The running instance starts its IPC listener right after acquiring the
+// single-instance mutex, before config load/bootstrap, but a launch racing
+// that narrow startup window should still get forwarded rather than failing
+// outright.
+const (
+	dialPipeRetryInterval = 100 * time.Millisecond
+	dialPipeRetryTimeout  = 5 * time.Second
+)
+
+// forwardToRunningInstance connects to the running instance's pipe and
+// forwards argv as a single ipcMessage line.
+func forwardToRunningInstance(exe string, argv []string) error {
+	conn, err := dialPipeWithRetry(pipeName(exe), dialPipeRetryTimeout)
+	if err != nil {
+		return fmt.Errorf("connect to running instance: %w", err)
+	}
+	defer conn.Close()
+
+	msg := argvToMessage(argv)
+	enc := json.NewEncoder(conn)
+	if err := enc.Encode(msg); err != nil {
+		return fmt.Errorf("send to running instance: %w", err)
+	}
+	return nil
+}
+
+// dialPipeWithRetry dials name, retrying on failure until timeout elapses.
+// The running instance's pipe may not exist yet if it's still early in
+// startup (the window between acquiring the mutex and the listener call
+// actually registering the pipe).
+func dialPipeWithRetry(name string, timeout time.Duration) (net.Conn, error) {
+	deadline := time.Now().Add(timeout)
+	var lastErr error
+	for {
+		conn, err := winio.DialPipe(name, nil)
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+		if time.Now().After(deadline) {
+			return nil, lastErr
+		}
+		time.Sleep(dialPipeRetryInterval)
+	}
+}
+
+// argvToMessage maps launcher argv (e.g. "open file.txt", "show-tray",
+// "quit") onto the wire protocol. Unrecognized argv is forwarded as "open".
+func argvToMessage(argv []string) ipcMessage {
+	if len(argv) == 0 {
+		return ipcMessage{Cmd: "show-tray"}
+	}
+	switch argv[0] {
+	case "show-tray", "quit":
+		return ipcMessage{Cmd: argv[0]}
+	case "open":
+		return ipcMessage{Cmd: "open", Args: argv[1:]}
+	default:
+		return ipcMessage{Cmd: "open", Args: argv}
+	}
+}
+
+// ipcServer listens on the per-instance named pipe from the moment this
+// process becomes the single instance, which is earlier than the Python
+// child's stdin pipe exists (config load, Python resolution, and
+// dependency bootstrap all happen in between). Messages received before
+// attachStdin is called are queued and flushed once it is, so a second
+// launch that races startup still gets forwarded instead of finding
+// nothing listening.
+type ipcServer struct {
+	listener net.Listener
+
+	mu     sync.Mutex
+	stdin  io.Writer
+	queued [][]byte
+}
+
+// startIPCServer listens on the per-instance named pipe. Call attachStdin
+// once the Python child's stdin pipe is wired up to start delivering
+// messages.
+func startIPCServer(exe string) (*ipcServer, error) {
+	l, err := winio.ListenPipe(pipeName(exe), nil)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", pipeName(exe), err)
+	}
+
+	srv := &ipcServer{listener: l}
+	go srv.acceptLoop()
+	return srv, nil
+}
+
+// attachStdin wires stdin as the destination for IPC messages, flushing any
+// that arrived while none was attached yet. The flush writes happen outside
+// the lock so a slow child doesn't stall concurrent handleConn goroutines.
+func (s *ipcServer) attachStdin(stdin io.Writer) {
+	s.mu.Lock()
+	s.stdin = stdin
+	queued := s.queued
+	s.queued = nil
+	s.mu.Unlock()
+
+	for _, line := range queued {
+		stdin.Write(line)
+	}
+}
+
+func (s *ipcServer) acceptLoop() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *ipcServer) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	var msg ipcMessage
+	if err := json.NewDecoder(conn).Decode(&msg); err != nil {
+		log("IPC: bad message: %v", err)
+		return
+	}
+
+	log("IPC: forwarding %+v to Kodex", msg)
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	// Hold the lock only long enough to read/queue, not for the write
+	// itself, so a child that's slow to drain its stdin can't stall other
+	// connections' handleConn goroutines (or a concurrent attachStdin).
+	s.mu.Lock()
+	stdin := s.stdin
+	if stdin == nil {
+		s.queued = append(s.queued, line)
+	}
+	s.mu.Unlock()
+
+	if stdin != nil {
+		stdin.Write(line)
+	}
+}
+
+func (s *ipcServer) close() {
+	s.listener.Close()
+}
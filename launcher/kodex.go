@@ -11,6 +11,7 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -28,6 +29,72 @@ func log(format string, args ...interface{}) {
 	}
 }
 
+const defaultModule = "kodex_py"
+
+func defaultArgv() []string { return []string{"run"} }
+
+// resolvePythonExe returns the interpreter path for profile, falling back to
+// the embedded python\python.exe. A relative interpreter path is resolved
+// against the launcher directory.
+func resolvePythonExe(dir string, profile launchProfile) string {
+	if profile.Interpreter == "" {
+		return filepath.Join(dir, "python", "python.exe")
+	}
+	return resolveAgainst(dir, profile.Interpreter)
+}
+
+func resolveAgainst(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// buildKodexCmd assembles the `python -m kodex_py run` invocation, or
+// whatever interpreter/module/script/argv the resolved profile overrides it
+// with. pythonExe and pythonArgs come from resolvePython. This is shared by
+// the interactive launcher and the Windows service runner.
+func buildKodexCmd(pythonExe string, pythonArgs []string, dir, appDir string, profile launchProfile) *exec.Cmd {
+	args := append([]string{}, pythonArgs...)
+	if profile.Script != "" {
+		args = append(args, resolveAgainst(dir, profile.Script))
+	} else {
+		module := profile.Module
+		if module == "" {
+			module = defaultModule
+		}
+		args = append(args, "-m", module)
+	}
+	argv := profile.Argv
+	if argv == nil {
+		argv = defaultArgv()
+	}
+	args = append(args, argv...)
+
+	cmd := exec.Command(pythonExe, args...)
+	cmd.Dir = dir
+	if profile.Cwd != "" {
+		cmd.Dir = resolveAgainst(dir, profile.Cwd)
+	}
+
+	env := os.Environ()
+	env = append(env, "PYTHONPATH="+appDir)
+	env = append(env, "KODEX_ROOT="+dir)
+	if debug {
+		env = append(env, "KODEX_DEBUG=1")
+	}
+	for k, v := range profile.Env {
+		env = append(env, k+"="+v)
+	}
+	cmd.Env = env
+
+	// Run the child in its own process group so a graceful shutdown can
+	// target it with CTRL_BREAK_EVENT without also signaling the launcher.
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+
+	return cmd
+}
+
 func main() {
 	// Detect debug mode from executable name
 	exe, err := os.Executable()
@@ -38,6 +105,64 @@ func main() {
 
 	debug = strings.Contains(strings.ToLower(filepath.Base(exe)), "debug")
 
+	profileName, args := extractProfileFlag(os.Args[1:])
+	noSupervise, args := extractFlag(args, "--no-supervise")
+
+	// `Kodex.exe logs` tails the current supervisor log and exits.
+	if len(args) > 0 && args[0] == "logs" {
+		if err := tailLogFile(64 * 1024); err != nil {
+			fmt.Println("ERROR:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Service management subcommands (install/uninstall/start/stop/status)
+	// are handled before the normal launch path and never fall through to it.
+	if len(args) > 0 && isServiceCommand(args[0]) {
+		if err := runServiceCommand(args[0], args[1:]); err != nil {
+			fmt.Println("ERROR:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// When the SCM starts us directly (no console, no args) we run as a
+	// service instead of a one-shot launcher.
+	if runningAsService() {
+		runService(exe)
+		return
+	}
+
+	// Don't spawn a second Python process if Kodex is already running;
+	// forward our argv to the running instance's tray instead.
+	acquired, err := acquireSingleInstance(exe)
+	if err != nil {
+		log("Single-instance check failed, continuing anyway: %v", err)
+	} else if !acquired {
+		if err := forwardToRunningInstance(exe, args); err != nil {
+			fmt.Println("ERROR: Kodex is already running:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Start the IPC listener as soon as we're the single instance, not once
+	// Python's stdin pipe exists further down — config load, Python
+	// resolution, and dependency bootstrap can take a while on first run,
+	// and a second launch arriving during that window should still be
+	// forwarded rather than finding no listener at all. Messages received
+	// before attachStdin is called are queued and flushed once it is.
+	var ipcSrv *ipcServer
+	if acquired {
+		if srv, serr := startIPCServer(exe); serr != nil {
+			log("IPC server not started: %v", serr)
+		} else {
+			ipcSrv = srv
+			defer ipcSrv.close()
+		}
+	}
+
 	if debug {
 		fmt.Println("╔════════════════════════════════════════╗")
 		fmt.Println("║         Kodex Debug Launcher           ║")
@@ -48,12 +173,29 @@ func main() {
 	dir := filepath.Dir(exe)
 	log("Launcher directory: %s", dir)
 
-	// Verify embedded Python exists
-	pythonExe := filepath.Join(dir, "python", "python.exe")
-	log("Looking for Python at: %s", pythonExe)
+	cfg, err := loadLaunchConfig(dir)
+	if err != nil {
+		msg := fmt.Sprintf("ERROR: %v", err)
+		if debug {
+			fmt.Println(msg)
+			fmt.Println("\nPress Enter to exit...")
+			fmt.Scanln()
+		}
+		os.Exit(1)
+	}
+	var profile launchProfile
+	if cfg != nil {
+		profile = cfg.resolveProfile(profileName)
+		if profile.Debug != nil {
+			debug = debug || *profile.Debug
+		}
+	}
+
+	log("Looking for Python at: %s", resolvePythonExe(dir, profile))
 
-	if _, err := os.Stat(pythonExe); os.IsNotExist(err) {
-		msg := fmt.Sprintf("ERROR: Embedded Python not found at:\n%s\n\nMake sure the 'python' folder exists next to this exe.", pythonExe)
+	pythonExe, pythonArgs, err := resolvePython(dir, profile)
+	if err != nil {
+		msg := fmt.Sprintf("ERROR: %v\n\nMake sure the 'python' folder exists next to this exe, or check kodex.json / .python-version.", err)
 		if debug {
 			fmt.Println(msg)
 			fmt.Println("\nPress Enter to exit...")
@@ -61,7 +203,7 @@ func main() {
 		}
 		os.Exit(1)
 	}
-	log("✓ Python found")
+	log("✓ Using Python: %s %s", pythonExe, strings.Join(pythonArgs, " "))
 
 	// Verify app directory exists
 	appDir := filepath.Join(dir, "app")
@@ -78,12 +220,26 @@ func main() {
 	}
 	log("✓ App directory found")
 
-	// Check for kodex_py module
-	kodexModule := filepath.Join(appDir, "kodex_py")
-	log("Looking for kodex_py module at: %s", kodexModule)
+	// Check for the kodex_py module, unless the profile points at an
+	// alternate module or a standalone script.
+	if profile.Script == "" && (profile.Module == "" || profile.Module == defaultModule) {
+		kodexModule := filepath.Join(appDir, "kodex_py")
+		log("Looking for kodex_py module at: %s", kodexModule)
 
-	if _, err := os.Stat(kodexModule); os.IsNotExist(err) {
-		msg := fmt.Sprintf("ERROR: kodex_py module not found at:\n%s", kodexModule)
+		if _, err := os.Stat(kodexModule); os.IsNotExist(err) {
+			msg := fmt.Sprintf("ERROR: kodex_py module not found at:\n%s", kodexModule)
+			if debug {
+				fmt.Println(msg)
+				fmt.Println("\nPress Enter to exit...")
+				fmt.Scanln()
+			}
+			os.Exit(1)
+		}
+		log("✓ kodex_py module found")
+	}
+
+	if err := ensureDepsInstalled(pythonExe, pythonArgs, appDir); err != nil {
+		msg := fmt.Sprintf("ERROR: Failed to install dependencies:\n%v", err)
 		if debug {
 			fmt.Println(msg)
 			fmt.Println("\nPress Enter to exit...")
@@ -91,26 +247,42 @@ func main() {
 		}
 		os.Exit(1)
 	}
-	log("✓ kodex_py module found")
-
-	// Build command
-	// -m kodex_py runs __main__.py which calls cli()
-	// We pass "run" to start the engine + tray
-	cmd := exec.Command(pythonExe, "-m", "kodex_py", "run")
-	cmd.Dir = dir
 
-	// Set environment
-	env := os.Environ()
-	env = append(env, "PYTHONPATH="+appDir)
-	env = append(env, "KODEX_ROOT="+dir)
-	if debug {
-		env = append(env, "KODEX_DEBUG=1")
+	// Wire stdin to a pipe so later launches can hand off `open`/`show-tray`
+	// /`quit` requests to this running instance via the IPC server.
+	var stdin io.Reader
+	if stdinR, stdinW, perr := os.Pipe(); perr == nil {
+		stdin = stdinR
+		if ipcSrv != nil {
+			ipcSrv.attachStdin(stdinW)
+		}
+	} else if ipcSrv != nil {
+		// No stdin pipe means attachStdin will never be called, so stop the
+		// listener now instead of silently queueing every IPC message a
+		// later launch sends for the rest of this process's life.
+		log("stdin pipe not created, stopping IPC listener: %v", perr)
+		ipcSrv.close()
+		ipcSrv = nil
 	}
-	cmd.Env = env
 
 	log("PYTHONPATH=%s", appDir)
 	log("KODEX_ROOT=%s", dir)
-	log("Command: %s -m kodex_py run", pythonExe)
+
+	if !noSupervise {
+		sup := supervisorConfig{}
+		if cfg != nil {
+			sup = cfg.Supervisor
+		}
+		if err := runSupervised(pythonExe, pythonArgs, dir, appDir, profile, sup, stdin, nil); err != nil {
+			fmt.Println("ERROR:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	cmd := buildKodexCmd(pythonExe, pythonArgs, dir, appDir, profile)
+	cmd.Stdin = stdin
+	log("Command: %s", strings.Join(cmd.Args, " "))
 
 	if debug {
 		// In debug mode, show Python's output
@@ -119,9 +291,7 @@ func main() {
 		fmt.Println("\n--- Python Output ---")
 	} else {
 		// In normal mode, hide Python's console window
-		cmd.SysProcAttr = &syscall.SysProcAttr{
-			HideWindow: true,
-		}
+		cmd.SysProcAttr.HideWindow = true
 	}
 
 	// Run
@@ -0,0 +1,71 @@
+//go:build windows
+
+// Minimal Win32 "please wait" dialog shown while ensureDepsInstalled runs in
+// GUI mode. In debug builds pip's own output already streams to the
+// console, so no dialog is shown.
+
+package main
+
+import (
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const progressDialogTitle = "Kodex Setup"
+
+const (
+	mbSystemModal = 0x00001000
+	wmClose       = 0x0010
+)
+
+var (
+	user32           = syscall.NewLazyDLL("user32.dll")
+	procMessageBoxW  = user32.NewProc("MessageBoxW")
+	procFindWindowW  = user32.NewProc("FindWindowW")
+	procPostMessageW = user32.NewProc("PostMessageW")
+)
+
+type progressDialog struct {
+	active bool
+}
+
+// startProgressDialog pops a small modal message box with message so a
+// non-technical user isn't staring at a blank screen during first-run
+// dependency install. Call stop() once the work finishes to dismiss it.
+func startProgressDialog(message string) *progressDialog {
+	pd := &progressDialog{active: !debug}
+	if !pd.active {
+		return pd
+	}
+
+	go func() {
+		title, _ := syscall.UTF16PtrFromString(progressDialogTitle)
+		text, _ := syscall.UTF16PtrFromString(message)
+		procMessageBoxW.Call(0, uintptr(unsafe.Pointer(text)), uintptr(unsafe.Pointer(title)), mbSystemModal)
+	}()
+	return pd
+}
+
+func (pd *progressDialog) stop() {
+	if !pd.active {
+		return
+	}
+
+	title, err := syscall.UTF16PtrFromString(progressDialogTitle)
+	if err != nil {
+		return
+	}
+
+	// The dialog is created on its own goroutine, so give the window a
+	// moment to exist before we try to close it.
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		hwnd, _, _ := procFindWindowW.Call(0, uintptr(unsafe.Pointer(title)))
+		if hwnd != 0 {
+			procPostMessageW.Call(hwnd, wmClose, 0, 0)
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,149 @@
+// Python interpreter resolution, with a fallback chain for machines where
+// the embedded runtime under python\python.exe has been stripped (e.g. by
+// AV quarantine or a portable install missing the python folder).
+//
+// Resolution order:
+//  1. the interpreter the profile/kodex.json points at (embedded by default)
+//  2. "python" on PATH
+//  3. the "py" launcher, pinned to the major.minor in a ".python-version"
+//     file next to the exe, if one exists
+//
+// Every candidate is verified by actually running it and checking
+// sys.version_info against compatiblePythonRange before it's accepted.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// compatiblePythonRange is the inclusive [min, max] (major, minor) versions
+// Kodex is known to run on.
+var compatiblePythonRange = [2][2]int{{3, 9}, {3, 12}}
+
+type pythonVersion struct {
+	major, minor int
+}
+
+func (v pythonVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.major, v.minor)
+}
+
+func (v pythonVersion) compatible() bool {
+	min, max := compatiblePythonRange[0], compatiblePythonRange[1]
+	if v.major < min[0] || v.major > max[0] {
+		return false
+	}
+	if v.major == min[0] && v.minor < min[1] {
+		return false
+	}
+	if v.major == max[0] && v.minor > max[1] {
+		return false
+	}
+	return true
+}
+
+// resolvePython finds a working Python interpreter for profile, falling
+// back to PATH and the py launcher when the configured/embedded one is
+// missing or incompatible. It returns the interpreter to exec and any
+// leading arguments it needs (e.g. "-3.11" for the py launcher).
+func resolvePython(dir string, profile launchProfile) (exe string, args []string, err error) {
+	var tried []string
+
+	candidate := resolvePythonExe(dir, profile)
+	if v, verr := probePythonVersion(candidate); verr == nil && v.compatible() {
+		return candidate, nil, nil
+	} else {
+		tried = append(tried, describeAttempt(candidate, v, verr))
+	}
+
+	pin := readPythonVersionPin(dir)
+
+	if found, lerr := exec.LookPath("python"); lerr == nil {
+		if v, verr := probePythonVersion(found); verr == nil && v.compatible() && matchesPin(v, pin) {
+			log("Falling back to PATH interpreter: %s (%s)", found, v)
+			return found, nil, nil
+		} else {
+			tried = append(tried, describeAttempt(found, v, verr))
+		}
+	}
+
+	if pin != "" {
+		if found, lerr := exec.LookPath("py"); lerr == nil {
+			pinArgs := []string{"-" + pin}
+			if v, verr := probePythonVersionWithArgs(found, pinArgs); verr == nil && v.compatible() {
+				log("Falling back to py launcher: py -%s (%s)", pin, v)
+				return found, pinArgs, nil
+			} else {
+				tried = append(tried, describeAttempt(found+" -"+pin, v, verr))
+			}
+		}
+	}
+
+	for _, attempt := range tried {
+		log("Python candidate rejected: %s", attempt)
+	}
+
+	return "", nil, fmt.Errorf(
+		"no compatible Python interpreter found (need %s-%s); tried: %s",
+		pythonVersion{compatiblePythonRange[0][0], compatiblePythonRange[0][1]},
+		pythonVersion{compatiblePythonRange[1][0], compatiblePythonRange[1][1]},
+		strings.Join(tried, "; "),
+	)
+}
+
+func describeAttempt(path string, v pythonVersion, err error) string {
+	if err != nil {
+		return fmt.Sprintf("%s: %v", path, err)
+	}
+	return fmt.Sprintf("%s: incompatible version %s", path, v)
+}
+
+const pythonVersionProbe = "import sys;print(sys.version_info[0], sys.version_info[1])"
+
+// probePythonVersion runs the candidate interpreter and parses its reported
+// version. It also confirms the executable exists so callers don't need a
+// separate os.Stat check.
+func probePythonVersion(exe string) (pythonVersion, error) {
+	if _, err := os.Stat(exe); err != nil {
+		return pythonVersion{}, err
+	}
+	return probePythonVersionWithArgs(exe, nil)
+}
+
+func probePythonVersionWithArgs(exe string, leadingArgs []string) (pythonVersion, error) {
+	args := append(append([]string{}, leadingArgs...), "-c", pythonVersionProbe)
+	out, err := exec.Command(exe, args...).Output()
+	if err != nil {
+		return pythonVersion{}, fmt.Errorf("run %s: %w", exe, err)
+	}
+	return parsePythonVersion(string(out))
+}
+
+func parsePythonVersion(out string) (pythonVersion, error) {
+	var v pythonVersion
+	if _, err := fmt.Sscanf(strings.TrimSpace(out), "%d %d", &v.major, &v.minor); err != nil {
+		return pythonVersion{}, fmt.Errorf("unexpected version output %q: %w", out, err)
+	}
+	return v, nil
+}
+
+// readPythonVersionPin reads a "major.minor" pin from a .python-version file
+// next to the exe, e.g. "3.11". Returns "" if the file is absent.
+func readPythonVersionPin(dir string) string {
+	data, err := os.ReadFile(resolveAgainst(dir, ".python-version"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func matchesPin(v pythonVersion, pin string) bool {
+	if pin == "" {
+		return true
+	}
+	return v.String() == pin
+}
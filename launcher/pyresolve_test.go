@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestPythonVersionCompatible(t *testing.T) {
+	cases := []struct {
+		v    pythonVersion
+		want bool
+	}{
+		{pythonVersion{3, 9}, true},
+		{pythonVersion{3, 12}, true},
+		{pythonVersion{3, 10}, true},
+		{pythonVersion{3, 8}, false},
+		{pythonVersion{3, 13}, false},
+		{pythonVersion{2, 7}, false},
+		{pythonVersion{4, 0}, false},
+	}
+	for _, c := range cases {
+		if got := c.v.compatible(); got != c.want {
+			t.Errorf("%s.compatible() = %v, want %v", c.v, got, c.want)
+		}
+	}
+}
+
+func TestParsePythonVersion(t *testing.T) {
+	v, err := parsePythonVersion("3 11\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v != (pythonVersion{3, 11}) {
+		t.Errorf("got %s, want 3.11", v)
+	}
+
+	if _, err := parsePythonVersion("not a version"); err == nil {
+		t.Error("expected error for malformed output, got nil")
+	}
+}
+
+func TestMatchesPin(t *testing.T) {
+	if !matchesPin(pythonVersion{3, 11}, "") {
+		t.Error("empty pin should match any version")
+	}
+	if !matchesPin(pythonVersion{3, 11}, "3.11") {
+		t.Error("exact pin should match")
+	}
+	if matchesPin(pythonVersion{3, 11}, "3.10") {
+		t.Error("mismatched pin should not match")
+	}
+}
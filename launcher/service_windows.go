@@ -0,0 +1,488 @@
+//go:build windows
+
+// Windows service installation and SCM runner mode for Kodex.
+//
+// Kodex.exe install [--user]   register as a service (or a per-user
+//                              Scheduled Task when not elevated / --user
+//                              is passed)
+// Kodex.exe uninstall          remove the service/task
+// Kodex.exe start              start the registered service/task
+// Kodex.exe stop               stop the running service/task
+// Kodex.exe status             print whether the service/task is running
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+const (
+	serviceName        = "KodexLauncher"
+	serviceDisplayName = "Kodex"
+	scheduledTaskName  = "KodexLauncher"
+)
+
+// installMode records which install path Kodex.exe install actually took,
+// so later start/stop/status calls (possibly run with different elevation
+// than the install) dispatch to the right one instead of guessing from
+// current elevation.
+type installMode string
+
+const (
+	installModeService       installMode = "service"
+	installModeScheduledTask installMode = "task"
+)
+
+func installModePath() string {
+	return filepath.Join(os.Getenv("LOCALAPPDATA"), "Kodex", "install-mode")
+}
+
+func writeInstallMode(mode installMode) error {
+	path := installModePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(mode), 0o644)
+}
+
+// readInstallMode reports the install mode recorded by a prior install, and
+// whether a marker was found at all. A missing marker means Kodex was
+// installed before this tracking existed; callers fall back to an
+// elevation-based guess in that case.
+func readInstallMode() (installMode, bool) {
+	data, err := os.ReadFile(installModePath())
+	if err != nil {
+		return "", false
+	}
+	return installMode(strings.TrimSpace(string(data))), true
+}
+
+func clearInstallMode() {
+	os.Remove(installModePath())
+}
+
+func isServiceCommand(arg string) bool {
+	switch arg {
+	case "install", "uninstall", "start", "stop", "status":
+		return true
+	default:
+		return false
+	}
+}
+
+// runningAsService reports whether this process was started by the Windows
+// Service Control Manager, as opposed to a console/Explorer launch.
+func runningAsService() bool {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		return false
+	}
+	return isService
+}
+
+func runServiceCommand(cmd string, args []string) error {
+	user := false
+	for _, a := range args {
+		if a == "--user" {
+			user = true
+		}
+	}
+
+	elevated := isElevated()
+	if !elevated {
+		user = true
+	}
+
+	// start/stop/status act on whichever mode install actually used, not on
+	// how this particular invocation happens to be elevated. Without a
+	// recorded mode (install predates this tracking, or install itself is
+	// running now) fall back to the elevation guess.
+	mode, haveMode := readInstallMode()
+	asTask := user
+	if haveMode {
+		asTask = mode == installModeScheduledTask
+	}
+
+	switch cmd {
+	case "install":
+		if user {
+			if err := installScheduledTask(); err != nil {
+				return err
+			}
+			return writeInstallMode(installModeScheduledTask)
+		}
+		if err := installService(); err != nil {
+			return err
+		}
+		return writeInstallMode(installModeService)
+	case "uninstall":
+		if asTask {
+			if err := uninstallScheduledTask(); err != nil {
+				return err
+			}
+			clearInstallMode()
+			return nil
+		}
+		if err := uninstallService(); err != nil {
+			// Fall back to the scheduled task in case it was installed
+			// with --user on this machine.
+			if terr := uninstallScheduledTask(); terr != nil {
+				return err
+			}
+		}
+		clearInstallMode()
+		return nil
+	case "start":
+		if asTask {
+			return startScheduledTask()
+		}
+		return startService()
+	case "stop":
+		if asTask {
+			return stopScheduledTask()
+		}
+		return stopService()
+	case "status":
+		return printServiceStatus(asTask)
+	default:
+		return fmt.Errorf("unknown service command: %s", cmd)
+	}
+}
+
+func isElevated() bool {
+	var sid *windows.SID
+	err := windows.AllocateAndInitializeSid(
+		&windows.SECURITY_NT_AUTHORITY,
+		2,
+		windows.SECURITY_BUILTIN_DOMAIN_RID,
+		windows.DOMAIN_ALIAS_RID_ADMINS,
+		0, 0, 0, 0, 0, 0,
+		&sid,
+	)
+	if err != nil {
+		return false
+	}
+	defer windows.FreeSid(sid)
+
+	token := windows.Token(0)
+	member, err := token.IsMember(sid)
+	if err != nil {
+		return false
+	}
+	return member
+}
+
+// installService registers Kodex.exe as an auto-restarting Windows service
+// pointed at "-m kodex_py run", propagating PYTHONPATH/KODEX_ROOT into the
+// service's own environment block via the registry.
+func installService() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("resolve executable path: %w", err)
+	}
+	dir := filepath.Dir(exe)
+	appDir := filepath.Join(dir, "app")
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connect to service manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(serviceName); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s is already installed", serviceName)
+	}
+
+	s, err := m.CreateService(serviceName, exe, mgr.Config{
+		DisplayName: serviceDisplayName,
+		Description: "Runs the Kodex engine headless at boot.",
+		StartType:   mgr.StartAutomatic,
+	})
+	if err != nil {
+		return fmt.Errorf("create service: %w", err)
+	}
+	defer s.Close()
+
+	if err := s.SetRecoveryActions([]mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: 5 * time.Second},
+		{Type: mgr.ServiceRestart, Delay: 30 * time.Second},
+		{Type: mgr.ServiceRestart, Delay: 60 * time.Second},
+	}, uint32((24 * time.Hour).Seconds())); err != nil {
+		return fmt.Errorf("configure auto-restart: %w", err)
+	}
+
+	env := []string{
+		"PYTHONPATH=" + appDir,
+		"KODEX_ROOT=" + dir,
+	}
+	if err := setServiceEnvironment(serviceName, env); err != nil {
+		return fmt.Errorf("set service environment: %w", err)
+	}
+
+	fmt.Printf("Service %q installed.\n", serviceDisplayName)
+	return nil
+}
+
+// setServiceEnvironment writes the service's per-process Environment
+// multi-string value so PYTHONPATH/KODEX_ROOT reach the Python child the
+// same way they do in the interactive launcher.
+func setServiceEnvironment(name string, env []string) error {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE,
+		`SYSTEM\CurrentControlSet\Services\`+name, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+
+	return k.SetStringsValue("Environment", env)
+}
+
+func uninstallService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connect to service manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed: %w", serviceName, err)
+	}
+	defer s.Close()
+
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("delete service: %w", err)
+	}
+	fmt.Printf("Service %q uninstalled.\n", serviceDisplayName)
+	return nil
+}
+
+func startService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connect to service manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed: %w", serviceName, err)
+	}
+	defer s.Close()
+
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("start service: %w", err)
+	}
+	fmt.Println("Service started.")
+	return nil
+}
+
+func stopService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connect to service manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed: %w", serviceName, err)
+	}
+	defer s.Close()
+
+	status, err := s.Control(svc.Stop)
+	if err != nil {
+		return fmt.Errorf("stop service: %w", err)
+	}
+	fmt.Printf("Service is now %v.\n", status.State)
+	return nil
+}
+
+func printServiceStatus(asTask bool) error {
+	if asTask {
+		return printScheduledTaskStatus()
+	}
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connect to service manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		fmt.Println("not installed")
+		return nil
+	}
+	defer s.Close()
+
+	status, err := s.Query()
+	if err != nil {
+		return fmt.Errorf("query service: %w", err)
+	}
+	fmt.Println(serviceStateName(status.State))
+	return nil
+}
+
+func serviceStateName(state svc.State) string {
+	switch state {
+	case svc.Running:
+		return "running"
+	case svc.Stopped:
+		return "stopped"
+	case svc.StartPending:
+		return "starting"
+	case svc.StopPending:
+		return "stopping"
+	default:
+		return "unknown"
+	}
+}
+
+// installScheduledTask registers a per-user logon task for non-admin
+// installs, mirroring the --user install path of tools like serviceman.
+func installScheduledTask() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("resolve executable path: %w", err)
+	}
+
+	cmd := exec.Command("schtasks", "/Create", "/TN", scheduledTaskName,
+		"/TR", fmt.Sprintf("%q", exe),
+		"/SC", "ONLOGON",
+		"/RL", "LIMITED",
+		"/F")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("schtasks /Create failed: %w (%s)", err, out)
+	}
+	fmt.Println("Scheduled task installed for current user.")
+	return nil
+}
+
+func uninstallScheduledTask() error {
+	cmd := exec.Command("schtasks", "/Delete", "/TN", scheduledTaskName, "/F")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("schtasks /Delete failed: %w (%s)", err, out)
+	}
+	fmt.Println("Scheduled task uninstalled.")
+	return nil
+}
+
+func startScheduledTask() error {
+	cmd := exec.Command("schtasks", "/Run", "/TN", scheduledTaskName)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("schtasks /Run failed: %w (%s)", err, out)
+	}
+	fmt.Println("Scheduled task started.")
+	return nil
+}
+
+func stopScheduledTask() error {
+	cmd := exec.Command("schtasks", "/End", "/TN", scheduledTaskName)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("schtasks /End failed: %w (%s)", err, out)
+	}
+	fmt.Println("Scheduled task stopped.")
+	return nil
+}
+
+func printScheduledTaskStatus() error {
+	cmd := exec.Command("schtasks", "/Query", "/TN", scheduledTaskName, "/FO", "LIST")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		fmt.Println("not installed")
+		return nil
+	}
+	fmt.Print(string(out))
+	return nil
+}
+
+// kodexService implements svc.Handler, reporting Start/Stop/Shutdown to the
+// SCM and forwarding termination requests to the Python child.
+type kodexService struct {
+	exe string
+}
+
+func runService(exe string) {
+	_ = svc.Run(serviceName, &kodexService{exe: exe})
+}
+
+// Execute runs Kodex under the same restart/backoff/rotating-log supervisor
+// used by the interactive launcher, so a headless service install gets the
+// same crash diagnostics as a console run.
+func (k *kodexService) Execute(args []string, r <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	const accepted = svc.AcceptStop | svc.AcceptShutdown
+
+	changes <- svc.Status{State: svc.StartPending}
+
+	dir := filepath.Dir(k.exe)
+	appDir := filepath.Join(dir, "app")
+
+	var cfg *launchConfig
+	var profile launchProfile
+	if loaded, err := loadLaunchConfig(dir); err == nil && loaded != nil {
+		cfg = loaded
+		profile = cfg.resolveProfile("")
+	}
+
+	pythonExe, pythonArgs, err := resolvePython(dir, profile)
+	if err != nil {
+		changes <- svc.Status{State: svc.Stopped}
+		return true, 1
+	}
+
+	if err := ensureDepsInstalled(pythonExe, pythonArgs, appDir); err != nil {
+		changes <- svc.Status{State: svc.Stopped}
+		return true, 1
+	}
+
+	sup := supervisorConfig{}
+	if cfg != nil {
+		sup = cfg.Supervisor
+	}
+
+	stop := make(chan struct{})
+	supervisorDone := make(chan error, 1)
+	go func() {
+		supervisorDone <- runSupervised(pythonExe, pythonArgs, dir, appDir, profile, sup, nil, stop)
+	}()
+
+	changes <- svc.Status{State: svc.Running, Accepts: accepted}
+
+	for {
+		select {
+		case err := <-supervisorDone:
+			if err != nil {
+				changes <- svc.Status{State: svc.Stopped}
+				return true, 1
+			}
+			changes <- svc.Status{State: svc.Stopped}
+			return false, 0
+		case req := <-r:
+			switch req.Cmd {
+			case svc.Interrogate:
+				changes <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending}
+				close(stop)
+				<-supervisorDone
+				changes <- svc.Status{State: svc.Stopped}
+				return false, 0
+			}
+		}
+	}
+}
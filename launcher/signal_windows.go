@@ -0,0 +1,44 @@
+//go:build windows
+
+// Graceful shutdown of the Python child: try CTRL_BREAK_EVENT first (the
+// child runs in its own process group per buildKodexCmd so this doesn't
+// also signal the launcher), and only kill it if it doesn't exit in time.
+
+package main
+
+import (
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+const ctrlBreakEvent = 1
+
+var (
+	kernel32                     = syscall.NewLazyDLL("kernel32.dll")
+	procGenerateConsoleCtrlEvent = kernel32.NewProc("GenerateConsoleCtrlEvent")
+)
+
+// stopChildGracefully signals cmd's process group with CTRL_BREAK_EVENT and
+// waits up to timeout for done to fire before falling back to a hard kill.
+func stopChildGracefully(cmd *exec.Cmd, done <-chan error, timeout time.Duration) {
+	if cmd.Process == nil {
+		return
+	}
+
+	pid := uintptr(cmd.Process.Pid)
+	if ret, _, err := procGenerateConsoleCtrlEvent.Call(ctrlBreakEvent, pid); ret == 0 {
+		log("CTRL_BREAK_EVENT failed (%v), killing Kodex", err)
+		_ = cmd.Process.Kill()
+		<-done
+		return
+	}
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log("Kodex did not exit within %s of CTRL_BREAK_EVENT, killing it", timeout)
+		_ = cmd.Process.Kill()
+		<-done
+	}
+}
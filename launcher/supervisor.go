@@ -0,0 +1,313 @@
+// Supervisor mode: restarts the Python child on crash with exponential
+// backoff, captures its stdout/stderr into a size-capped rotating log even
+// in GUI mode, and writes a crash report when it keeps dying. Pass
+// --no-supervise to fall back to the old one-shot cmd.Run() behavior.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// supervisorConfig is the "supervisor" block of kodex.json.
+type supervisorConfig struct {
+	MaxRestarts       int     `json:"maxRestarts,omitempty"`
+	BackoffSeconds    float64 `json:"backoffSeconds,omitempty"`
+	MaxBackoffSeconds float64 `json:"maxBackoffSeconds,omitempty"`
+	LogMaxBytes       int64   `json:"logMaxBytes,omitempty"`
+}
+
+const (
+	defaultMaxRestarts       = 5
+	defaultBackoffSeconds    = 2
+	defaultMaxBackoffSeconds = 60
+	defaultLogMaxBytes       = 5 * 1024 * 1024
+)
+
+func (s supervisorConfig) withDefaults() supervisorConfig {
+	if s.MaxRestarts == 0 {
+		s.MaxRestarts = defaultMaxRestarts
+	}
+	if s.BackoffSeconds == 0 {
+		s.BackoffSeconds = defaultBackoffSeconds
+	}
+	if s.MaxBackoffSeconds == 0 {
+		s.MaxBackoffSeconds = defaultMaxBackoffSeconds
+	}
+	if s.LogMaxBytes == 0 {
+		s.LogMaxBytes = defaultLogMaxBytes
+	}
+	return s
+}
+
+// logDir is where the supervisor writes its rotating log and crash
+// reports: %LOCALAPPDATA%\Kodex\logs.
+func logDir() string {
+	return filepath.Join(os.Getenv("LOCALAPPDATA"), "Kodex", "logs")
+}
+
+func currentLogPath() string {
+	return filepath.Join(logDir(), "kodex.log")
+}
+
+// runSupervised runs the Python child under buildKodexCmd, restarting it on
+// non-zero exit up to sup.MaxRestarts times with exponential backoff. It
+// returns the error from the final, non-restarted exit (nil on a clean
+// exit or a requested stop). Closing stop asks the current child to shut
+// down gracefully (CTRL_BREAK_EVENT, then a kill if it doesn't respond)
+// and ends the supervisor loop without restarting it.
+func runSupervised(pythonExe string, pythonArgs []string, dir, appDir string, profile launchProfile, sup supervisorConfig, stdin io.Reader, stop <-chan struct{}) error {
+	sup = sup.withDefaults()
+
+	if err := os.MkdirAll(logDir(), 0o755); err != nil {
+		return fmt.Errorf("create log directory: %w", err)
+	}
+
+	rotLog, err := newRotatingLogWriter(currentLogPath(), sup.LogMaxBytes)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	defer rotLog.Close()
+
+	tail := newLineRingBuffer(200)
+
+	attempt := 0
+	for {
+		cmd := buildKodexCmd(pythonExe, pythonArgs, dir, appDir, profile)
+		cmd.Stdin = stdin
+		out := io.MultiWriter(rotLog, tail)
+		cmd.Stdout = out
+		cmd.Stderr = out
+		if debug {
+			cmd.Stdout = io.MultiWriter(out, os.Stdout)
+			cmd.Stderr = io.MultiWriter(out, os.Stderr)
+		} else {
+			cmd.SysProcAttr.HideWindow = true
+		}
+
+		log("Starting Kodex (attempt %d)...", attempt+1)
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("start Kodex: %w", err)
+		}
+
+		done := make(chan error, 1)
+		go func() { done <- cmd.Wait() }()
+
+		var runErr error
+		select {
+		case runErr = <-done:
+		case <-stop:
+			log("Stop requested, shutting down Kodex gracefully...")
+			stopChildGracefully(cmd, done, 5*time.Second)
+			return nil
+		}
+
+		if runErr == nil {
+			log("Kodex exited cleanly")
+			return nil
+		}
+
+		exitCode := -1
+		if exitErr, ok := runErr.(*exec.ExitError); ok {
+			exitCode = exitErr.ExitCode()
+		}
+		log("Kodex exited with code %d: %v", exitCode, runErr)
+
+		attempt++
+		if attempt > sup.MaxRestarts {
+			writeCrashReport(exitCode, tail.Lines())
+			return fmt.Errorf("Kodex crashed %d times, giving up: %w", attempt, runErr)
+		}
+
+		backoff := time.Duration(sup.BackoffSeconds*float64(time.Second)) * time.Duration(1<<uint(attempt-1))
+		max := time.Duration(sup.MaxBackoffSeconds * float64(time.Second))
+		if backoff > max {
+			backoff = max
+		}
+		log("Restarting in %s (%d/%d)", backoff, attempt, sup.MaxRestarts)
+		select {
+		case <-time.After(backoff):
+		case <-stop:
+			return nil
+		}
+	}
+}
+
+func writeCrashReport(exitCode int, logTail []string) {
+	path := filepath.Join(logDir(), fmt.Sprintf("crash-%s.txt", time.Now().Format("20060102-150405")))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Kodex crash report\n")
+	fmt.Fprintf(&b, "Time: %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Exit code: %d\n\n", exitCode)
+	fmt.Fprintf(&b, "Environment:\n")
+	for _, kv := range os.Environ() {
+		fmt.Fprintf(&b, "  %s\n", redactEnvEntry(kv))
+	}
+	fmt.Fprintf(&b, "\nLast %d log lines:\n", len(logTail))
+	for _, line := range logTail {
+		fmt.Fprintln(&b, line)
+	}
+
+	// Users attach this file to support requests. It is written owner-only
+	// (0o600) where the OS honors the mode; on Windows Go applies only the
+	// read-only bit, so access there follows the ACL of the logs directory
+	// under %LOCALAPPDATA%.
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		log("Failed to write crash report: %v", err)
+		return
+	}
+	log("Crash report written to %s", path)
+}
+
+// secretEnvPatterns are substrings of environment variable names that
+// commonly hold credentials. Matching is case-insensitive.
+var secretEnvPatterns = []string{"TOKEN", "SECRET", "KEY", "PASSWORD", "PASSWD", "CREDENTIAL", "AUTH"}
+
+// redactEnvEntry blanks the value of a "NAME=value" environment entry whose
+// name looks like it holds a credential, so crash reports don't leak secrets
+// sitting in the process environment.
+func redactEnvEntry(kv string) string {
+	name, _, ok := strings.Cut(kv, "=")
+	if !ok {
+		return kv
+	}
+	upper := strings.ToUpper(name)
+	for _, pattern := range secretEnvPatterns {
+		if strings.Contains(upper, pattern) {
+			return name + "=<redacted>"
+		}
+	}
+	return kv
+}
+
+// tailLogFile prints the last n bytes of the current log file, for the
+// `Kodex.exe logs` subcommand.
+func tailLogFile(n int64) error {
+	f, err := os.Open(currentLogPath())
+	if err != nil {
+		return fmt.Errorf("open %s: %w", currentLogPath(), err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	offset := info.Size() - n
+	if offset < 0 {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	_, err = io.Copy(os.Stdout, f)
+	return err
+}
+
+// rotatingLogWriter appends to path, renaming it to path+".1" (overwriting
+// any previous backup) once it exceeds maxBytes.
+type rotatingLogWriter struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	file     *os.File
+	size     int64
+}
+
+func newRotatingLogWriter(path string, maxBytes int64) (*rotatingLogWriter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &rotatingLogWriter{path: path, maxBytes: maxBytes, file: f, size: info.Size()}, nil
+}
+
+func (w *rotatingLogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.size+int64(len(p)) > w.maxBytes {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *rotatingLogWriter) rotate() error {
+	w.file.Close()
+	backup := w.path + ".1"
+	os.Remove(backup)
+	os.Rename(w.path, backup)
+
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	w.file = f
+	w.size = 0
+	return nil
+}
+
+func (w *rotatingLogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+// lineRingBuffer keeps the last n lines written to it, for crash reports.
+type lineRingBuffer struct {
+	mu    sync.Mutex
+	n     int
+	lines []string
+	carry []byte
+}
+
+func newLineRingBuffer(n int) *lineRingBuffer {
+	return &lineRingBuffer{n: n}
+}
+
+func (b *lineRingBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.carry = append(b.carry, p...)
+	for {
+		i := bytes.IndexByte(b.carry, '\n')
+		if i < 0 {
+			break
+		}
+		b.push(string(b.carry[:i]))
+		b.carry = b.carry[i+1:]
+	}
+	return len(p), nil
+}
+
+func (b *lineRingBuffer) push(line string) {
+	b.lines = append(b.lines, line)
+	if len(b.lines) > b.n {
+		b.lines = b.lines[len(b.lines)-b.n:]
+	}
+}
+
+func (b *lineRingBuffer) Lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string{}, b.lines...)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLineRingBufferKeepsLastN(t *testing.T) {
+	b := newLineRingBuffer(2)
+	b.Write([]byte("one\ntwo\nthree\n"))
+
+	got := b.Lines()
+	want := []string{"two", "three"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %v, want %v", got, want)
+	}
+}
+
+func TestLineRingBufferCarriesPartialLine(t *testing.T) {
+	b := newLineRingBuffer(10)
+	b.Write([]byte("partial"))
+	b.Write([]byte(" line\n"))
+
+	got := b.Lines()
+	want := []string{"partial line"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %v, want %v", got, want)
+	}
+}
+
+func TestRotatingLogWriterRotatesOnOverflow(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kodex.log")
+
+	w, err := newRotatingLogWriter(path, 10)
+	if err != nil {
+		t.Fatalf("newRotatingLogWriter: %v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("12345678")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	// This write would push size past maxBytes, so it should rotate first.
+	if _, err := w.Write([]byte("abcdef")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	backup, err := os.ReadFile(path + ".1")
+	if err != nil {
+		t.Fatalf("read backup: %v", err)
+	}
+	if string(backup) != "12345678" {
+		t.Errorf("backup contents = %q, want %q", backup, "12345678")
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read current: %v", err)
+	}
+	if string(current) != "abcdef" {
+		t.Errorf("current contents = %q, want %q", current, "abcdef")
+	}
+}
+
+func TestRedactEnvEntry(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"API_TOKEN=abc123", "API_TOKEN=<redacted>"},
+		{"AWS_SECRET_ACCESS_KEY=shh", "AWS_SECRET_ACCESS_KEY=<redacted>"},
+		{"DB_PASSWORD=hunter2", "DB_PASSWORD=<redacted>"},
+		{"PATH=/usr/bin", "PATH=/usr/bin"},
+		{"KODEX_ROOT=C:\\Kodex", "KODEX_ROOT=C:\\Kodex"},
+		{"no_equals_sign", "no_equals_sign"},
+	}
+	for _, c := range cases {
+		if got := redactEnvEntry(c.in); got != c.want {
+			t.Errorf("redactEnvEntry(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}